@@ -0,0 +1,124 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// invalidBatchRequestCode is the JSON-RPC InvalidRequest code used when an
+// empty batch is received.
+const invalidBatchRequestCode = -32600
+
+// Batch is a JSON-RPC batch: an ordered sequence of raw request or
+// notification messages sent together in a single JSON array, per the
+// spec's provision that a client "may send an Array filled with Request
+// objects". Elements are kept as raw messages because an individual
+// element may fail to decode without invalidating the rest of the batch.
+type Batch []json.RawMessage
+
+// MarshalJSON implements json.Marshaler, preserving element order. A nil
+// Batch marshals to the JSON null literal rather than an empty array.
+func (b Batch) MarshalJSON() ([]byte, error) {
+	if b == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal([]json.RawMessage(b))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, preserving element order.
+func (b *Batch) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*b = raw
+	return nil
+}
+
+// BatchResponse is the ordered collection of Responses produced for a
+// Batch, with notification entries omitted.
+type BatchResponse []Response[any, any]
+
+// MarshalJSON implements json.Marshaler. A nil BatchResponse marshals to
+// the JSON null literal rather than an empty array.
+func (br BatchResponse) MarshalJSON() ([]byte, error) {
+	if br == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal([]Response[any, any](br))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (br *BatchResponse) UnmarshalJSON(data []byte) error {
+	var raw []Response[any, any]
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*br = raw
+	return nil
+}
+
+// Handler processes one decoded request or notification and returns its
+// result, or an error to be reported back as a JSON-RPC error.
+type Handler func(ctx context.Context, req Request[json.RawMessage]) (any, error)
+
+// DispatchBatch decodes and dispatches a raw JSON-RPC batch, invoking
+// handler for every element concurrently and reassembling the responses
+// in the original order. It implements the spec's batch edge cases: an
+// empty batch yields a single InvalidRequest response with a null id; a
+// batch consisting entirely of notifications yields no response at all
+// (a nil BatchResponse, not an empty one); and a malformed element
+// produces its own error response without affecting its siblings.
+func DispatchBatch(ctx context.Context, batch Batch, handler Handler) (BatchResponse, error) {
+	if len(batch) == 0 {
+		return BatchResponse{{
+			Error: Error[any]{Code: invalidBatchRequestCode, Message: "invalid request"},
+		}}, nil
+	}
+
+	results := make([]*Response[any, any], len(batch))
+	var wg sync.WaitGroup
+	for i, raw := range batch {
+		wg.Add(1)
+		go func(i int, raw json.RawMessage) {
+			defer wg.Done()
+			results[i] = dispatchOne(ctx, raw, handler)
+		}(i, raw)
+	}
+	wg.Wait()
+
+	out := make(BatchResponse, 0, len(results))
+	for _, r := range results {
+		if r != nil {
+			out = append(out, *r)
+		}
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// dispatchOne decodes and invokes handler for a single batch element,
+// returning nil for notifications (which never produce a response).
+func dispatchOne(ctx context.Context, raw json.RawMessage, handler Handler) *Response[any, any] {
+	mt, err := getMessageType(raw)
+	if err != nil {
+		return &Response[any, any]{Error: convertError(err)}
+	}
+
+	var req Request[json.RawMessage]
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return &Response[any, any]{Error: convertError(err)}
+	}
+
+	result, err := handler(ctx, req)
+	if mt == messageNotification {
+		return nil
+	}
+	if err != nil {
+		return &Response[any, any]{ID: req.ID, Error: convertError(err)}
+	}
+	return &Response[any, any]{ID: req.ID, Result: result}
+}
@@ -0,0 +1,120 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestBatch_MarshalUnmarshal(t *testing.T) {
+	input := `[{"jsonrpc":"2.0","id":1,"method":"a"},{"jsonrpc":"2.0","method":"b"}]`
+
+	var batch Batch
+	if err := json.Unmarshal([]byte(input), &batch); err != nil {
+		t.Fatalf("UnmarshalJSON() error: %v", err)
+	}
+	if len(batch) != 2 {
+		t.Fatalf("len(batch) = %d; want 2", len(batch))
+	}
+
+	result, err := json.Marshal(batch)
+	if err != nil {
+		t.Fatalf("MarshalJSON() error: %v", err)
+	}
+	if string(result) != input {
+		t.Errorf("MarshalJSON() = %v; want %v", string(result), input)
+	}
+}
+
+func TestBatch_MarshalJSON_Nil(t *testing.T) {
+	var batch Batch
+	result, err := json.Marshal(batch)
+	if err != nil {
+		t.Fatalf("MarshalJSON() error: %v", err)
+	}
+	if string(result) != "null" {
+		t.Errorf("MarshalJSON() = %v; want null", string(result))
+	}
+}
+
+func echoHandler(_ context.Context, req Request[json.RawMessage]) (any, error) {
+	if req.Method == "fail" {
+		return nil, errors.New("boom")
+	}
+	return req.Method, nil
+}
+
+func TestDispatchBatch_Mixed(t *testing.T) {
+	batch := Batch{
+		json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"one"}`),
+		json.RawMessage(`{"jsonrpc":"2.0","method":"notify"}`),
+		json.RawMessage(`{"jsonrpc":"2.0","id":2,"method":"fail"}`),
+	}
+
+	resp, err := DispatchBatch(context.Background(), batch, echoHandler)
+	if err != nil {
+		t.Fatalf("DispatchBatch() error: %v", err)
+	}
+	if len(resp) != 2 {
+		t.Fatalf("len(resp) = %d; want 2", len(resp))
+	}
+
+	byID := map[string]Response[any, any]{}
+	for _, r := range resp {
+		byID[r.ID.String()] = r
+	}
+
+	if got := byID["1"].Result; got != "one" {
+		t.Errorf("resp[id=1].Result = %v; want %q", got, "one")
+	}
+	if got := byID["2"].Error.Code; got != -32000 {
+		t.Errorf("resp[id=2].Error.Code = %d; want -32000", got)
+	}
+}
+
+func TestDispatchBatch_AllNotifications(t *testing.T) {
+	batch := Batch{
+		json.RawMessage(`{"jsonrpc":"2.0","method":"notify1"}`),
+		json.RawMessage(`{"jsonrpc":"2.0","method":"notify2"}`),
+	}
+
+	resp, err := DispatchBatch(context.Background(), batch, echoHandler)
+	if err != nil {
+		t.Fatalf("DispatchBatch() error: %v", err)
+	}
+	if resp != nil {
+		t.Errorf("DispatchBatch() = %v; want nil", resp)
+	}
+}
+
+func TestDispatchBatch_Empty(t *testing.T) {
+	resp, err := DispatchBatch(context.Background(), Batch{}, echoHandler)
+	if err != nil {
+		t.Fatalf("DispatchBatch() error: %v", err)
+	}
+	if len(resp) != 1 {
+		t.Fatalf("len(resp) = %d; want 1", len(resp))
+	}
+	if !resp[0].ID.IsNull() {
+		t.Errorf("resp[0].ID = %v; want null", resp[0].ID)
+	}
+	if resp[0].Error.Code != -32600 {
+		t.Errorf("resp[0].Error.Code = %d; want -32600", resp[0].Error.Code)
+	}
+}
+
+func TestDispatchBatch_InvalidElement(t *testing.T) {
+	batch := Batch{
+		json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"one"}`),
+		json.RawMessage(`{"jsonrpc":"1.0","method":"bad"}`),
+	}
+
+	resp, err := DispatchBatch(context.Background(), batch, echoHandler)
+	if err != nil {
+		t.Fatalf("DispatchBatch() error: %v", err)
+	}
+	if len(resp) != 2 {
+		t.Fatalf("len(resp) = %d; want 2", len(resp))
+	}
+}
@@ -0,0 +1,241 @@
+package jsonrpc2
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Framing selects how messages are delimited on the wire.
+type Framing int
+
+const (
+	// HeaderFraming uses LSP-style "Content-Length: N\r\n\r\n<json>" framing.
+	HeaderFraming Framing = iota
+	// NDJSONFraming uses newline-delimited JSON, one message per line.
+	NDJSONFraming
+)
+
+// Conn is a framed JSON-RPC connection over an io.Reader/io.Writer pair,
+// such as a subprocess's stdio or a socket. It is the piece needed to
+// actually run MCP over stdio: ReadMessage/WriteMessage handle framing,
+// and ServeConn layers request/notification dispatch and response
+// correlation on top.
+type Conn struct {
+	framing Framing
+	r       *bufio.Reader
+	w       io.Writer
+
+	rmu sync.Mutex
+	wmu sync.Mutex
+
+	pendingMu sync.Mutex
+	pending   map[ID]chan Response[json.RawMessage, json.RawMessage]
+}
+
+// NewConn creates a Conn that reads from r and writes to w using the
+// given Framing.
+func NewConn(r io.Reader, w io.Writer, framing Framing) *Conn {
+	return &Conn{
+		framing: framing,
+		r:       bufio.NewReader(r),
+		w:       w,
+		pending: make(map[ID]chan Response[json.RawMessage, json.RawMessage]),
+	}
+}
+
+// ReadMessage reads and classifies the next frame. It is safe for one
+// reader goroutine at a time; ServeConn is the typical caller.
+func (c *Conn) ReadMessage() (json.RawMessage, messageType, error) {
+	c.rmu.Lock()
+	defer c.rmu.Unlock()
+
+	var data []byte
+	var err error
+	if c.framing == NDJSONFraming {
+		data, err = c.r.ReadBytes('\n')
+		if err != nil {
+			return nil, 0, err
+		}
+		data = bytes.TrimSpace(data)
+	} else {
+		data, err = readHeaderFrame(c.r)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	mt, err := getMessageType(data)
+	if err != nil {
+		return json.RawMessage(data), 0, err
+	}
+	return json.RawMessage(data), mt, nil
+}
+
+// readHeaderFrame parses LSP-style headers (case-insensitive names;
+// Content-Type is tolerated and ignored) and returns exactly
+// Content-Length bytes of body.
+func readHeaderFrame(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("jsonrpc2: malformed header %q", line)
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "content-length":
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("jsonrpc2: invalid Content-Length %q: %w", value, err)
+			}
+			length = n
+		case "content-type":
+			// Tolerated and ignored.
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("jsonrpc2: missing Content-Length header")
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// WriteMessage serializes v and writes it as a single frame. It is safe
+// for concurrent callers.
+func (c *Conn) WriteMessage(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	if c.framing == NDJSONFraming {
+		_, err = c.w.Write(append(data, '\n'))
+		return err
+	}
+
+	if _, err := io.WriteString(c.w, fmt.Sprintf("Content-Length: %d\r\n\r\n", len(data))); err != nil {
+		return err
+	}
+	_, err = c.w.Write(data)
+	return err
+}
+
+// Call sends req and blocks until ServeConn delivers a response carrying
+// a matching ID, or until ctx is done. req.ID must be non-null.
+func (c *Conn) Call(ctx context.Context, req Request[any]) (Response[json.RawMessage, json.RawMessage], error) {
+	ch := make(chan Response[json.RawMessage, json.RawMessage], 1)
+
+	c.pendingMu.Lock()
+	c.pending[req.ID] = ch
+	c.pendingMu.Unlock()
+	defer func() {
+		c.pendingMu.Lock()
+		delete(c.pending, req.ID)
+		c.pendingMu.Unlock()
+	}()
+
+	if err := c.WriteMessage(req); err != nil {
+		return Response[json.RawMessage, json.RawMessage]{}, err
+	}
+
+	select {
+	case resp := <-ch:
+		return resp, nil
+	case <-ctx.Done():
+		return Response[json.RawMessage, json.RawMessage]{}, ctx.Err()
+	}
+}
+
+// ServeConn reads frames from c until the transport itself fails
+// (io.EOF on clean shutdown, or a framing error that desyncs the
+// stream), dispatching each request and notification to handler on its
+// own goroutine and delivering each response to the Call waiting on its
+// ID, if any. A frame that reads fine but fails JSON-RPC validation
+// (wrong version, unrecognizable shape) does not end the session: it is
+// reported back as an InvalidRequest response and the loop continues.
+func (c *Conn) ServeConn(ctx context.Context, handler Handler) error {
+	for {
+		data, mt, err := c.ReadMessage()
+		if err != nil {
+			if data == nil {
+				return err
+			}
+			_ = c.WriteMessage(Response[any, any]{Error: NewInvalidRequest(err.Error())})
+			continue
+		}
+
+		switch mt {
+		case messageRequest, messageNotification:
+			go c.handleRequest(ctx, data, mt, handler)
+		case messageResponse:
+			var resp Response[json.RawMessage, json.RawMessage]
+			if err := json.Unmarshal(data, &resp); err == nil {
+				c.deliver(resp)
+			}
+		case messageBatch:
+			go c.handleBatch(ctx, data, handler)
+		}
+	}
+}
+
+func (c *Conn) handleRequest(ctx context.Context, data json.RawMessage, mt messageType, handler Handler) {
+	var req Request[json.RawMessage]
+	if err := json.Unmarshal(data, &req); err != nil {
+		return
+	}
+
+	result, err := handler(ctx, req)
+	if mt == messageNotification {
+		return
+	}
+
+	resp := Response[any, any]{ID: req.ID, Result: result}
+	if err != nil {
+		resp = Response[any, any]{ID: req.ID, Error: convertError(err)}
+	}
+	_ = c.WriteMessage(resp)
+}
+
+func (c *Conn) handleBatch(ctx context.Context, data json.RawMessage, handler Handler) {
+	var batch Batch
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return
+	}
+
+	resp, err := DispatchBatch(ctx, batch, handler)
+	if err != nil || resp == nil {
+		return
+	}
+	_ = c.WriteMessage(resp)
+}
+
+func (c *Conn) deliver(resp Response[json.RawMessage, json.RawMessage]) {
+	c.pendingMu.Lock()
+	ch, ok := c.pending[resp.ID]
+	c.pendingMu.Unlock()
+
+	if ok {
+		ch <- resp
+	}
+}
@@ -1,203 +1,239 @@
 package jsonrpc2
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"strings"
 	"testing"
+	"time"
 )
 
-func TestID_IsNull(t *testing.T) {
-	tests := []struct {
-		id       ID
-		expected bool
-	}{
-		{id: ID{value: nil}, expected: true},
-		{id: ID{value: "test"}, expected: false},
-		{id: ID{value: 123}, expected: false},
+func TestConn_HeaderFraming_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	conn := NewConn(&buf, &buf, HeaderFraming)
+
+	req := Request[any]{ID: ID{value: int64(1)}, Method: "testMethod", Params: map[string]any{"a": 1}}
+	if err := conn.WriteMessage(req); err != nil {
+		t.Fatalf("WriteMessage() error: %v", err)
 	}
 
-	for _, test := range tests {
-		if result := test.id.IsNull(); result != test.expected {
-			t.Errorf("ID(%v).IsNull() = %v; want %v", test.id, result, test.expected)
-		}
+	data, mt, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage() error: %v", err)
+	}
+	if mt != messageRequest {
+		t.Errorf("messageType = %v; want messageRequest", mt)
+	}
+
+	var got Request[map[string]any]
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal() error: %v", err)
+	}
+	if got.Method != "testMethod" {
+		t.Errorf("Method = %v; want testMethod", got.Method)
 	}
 }
 
-func TestID_String(t *testing.T) {
-	tests := []struct {
-		id       ID
-		expected string
-	}{
-		{id: ID{value: "test"}, expected: "test"},
-		{id: ID{value: 123}, expected: "123"},
-		{id: ID{value: nil}, expected: ""},
+func TestConn_NDJSONFraming_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	conn := NewConn(&buf, &buf, NDJSONFraming)
+
+	req := Request[any]{Method: "notify"}
+	if err := conn.WriteMessage(req); err != nil {
+		t.Fatalf("WriteMessage() error: %v", err)
+	}
+	if !strings.HasSuffix(buf.String(), "\n") {
+		t.Errorf("written frame missing trailing newline: %q", buf.String())
 	}
 
-	for _, test := range tests {
-		if result := test.id.String(); result != test.expected {
-			t.Errorf("ID(%v).String() = %v; want %v", test.id, result, test.expected)
-		}
+	_, mt, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage() error: %v", err)
+	}
+	if mt != messageNotification {
+		t.Errorf("messageType = %v; want messageNotification", mt)
 	}
 }
 
-func TestID_UnmarshalJSON(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected ID
-	}{
-		{input: `"test"`, expected: ID{value: "test"}},
-		{input: `123`, expected: ID{value: 123}},
-		{input: `null`, expected: ID{value: nil}},
-	}
+func TestReadHeaderFrame_ContentTypeIgnored(t *testing.T) {
+	body := `{"jsonrpc":"2.0","method":"testMethod"}`
+	frame := fmt.Sprintf("Content-Type: application/vscode-jsonrpc; charset=utf-8\r\nContent-Length: %d\r\n\r\n%s", len(body), body)
 
-	for _, test := range tests {
-		var id ID
-		if err := json.Unmarshal([]byte(test.input), &id); err != nil {
-			t.Errorf("UnmarshalJSON(%v) error: %v", test.input, err)
-		}
-		if id != test.expected {
-			t.Errorf("UnmarshalJSON(%v) = %v; want %v", test.input, id, test.expected)
-		}
+	data, err := readHeaderFrame(bufio.NewReader(strings.NewReader(frame)))
+	if err != nil {
+		t.Fatalf("readHeaderFrame() error: %v", err)
+	}
+	if string(data) != body {
+		t.Errorf("readHeaderFrame() = %q; want %q", data, body)
 	}
 }
 
-func TestID_MarshalJSON(t *testing.T) {
-	tests := []struct {
-		id       ID
-		expected string
-	}{
-		{id: ID{value: "test"}, expected: `"test"`},
-		{id: ID{value: 123}, expected: `123`},
-		{id: ID{value: nil}, expected: `null`},
+func TestServeConn_RequestDispatchAndResponse(t *testing.T) {
+	serverIn, clientToServer := io.Pipe()
+	clientFromServer, serverOut := io.Pipe()
+
+	conn := NewConn(serverIn, serverOut, HeaderFraming)
+
+	handler := func(_ context.Context, req Request[json.RawMessage]) (any, error) {
+		return "echo:" + req.Method, nil
 	}
 
-	for _, test := range tests {
-		result, err := json.Marshal(test.id)
-		if err != nil {
-			t.Errorf("MarshalJSON(%v) error: %v", test.id, err)
-		}
-		if string(result) != test.expected {
-			t.Errorf("MarshalJSON(%v) = %v; want %v", test.id, string(result), test.expected)
-		}
+	serveDone := make(chan error, 1)
+	go func() { serveDone <- conn.ServeConn(context.Background(), handler) }()
+
+	reqBody := `{"jsonrpc":"2.0","id":1,"method":"ping"}`
+	go func() {
+		_, _ = io.WriteString(clientToServer, fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(reqBody), reqBody))
+		clientToServer.Close()
+	}()
+
+	respData, err := readHeaderFrame(bufio.NewReader(clientFromServer))
+	if err != nil {
+		t.Fatalf("readHeaderFrame() error: %v", err)
+	}
+
+	var resp Response[string, any]
+	if err := json.Unmarshal(respData, &resp); err != nil {
+		t.Fatalf("json.Unmarshal() error: %v", err)
+	}
+	if resp.Result != "echo:ping" {
+		t.Errorf("Result = %v; want echo:ping", resp.Result)
+	}
+
+	if err := <-serveDone; err != io.EOF {
+		t.Errorf("ServeConn() error = %v; want io.EOF", err)
 	}
 }
 
-func TestRequest_MarshalJSON(t *testing.T) {
-	tests := []struct {
-		req      *Request[any]
-		expected string
-	}{
-		{
-			req:      &Request[any]{ID: ID{value: "1"}, Method: "testMethod", Params: map[string]any{"param1": "value1"}},
-			expected: `{"jsonrpc":"2.0","id":"1","method":"testMethod","params":{"param1":"value1"}}`,
-		},
-		{
-			req:      &Request[any]{ID: ID{value: 1}, Method: "testMethod", Params: map[string]any{"param1": "value1"}},
-			expected: `{"jsonrpc":"2.0","id":1,"method":"testMethod","params":{"param1":"value1"}}`,
-		},
-		{
-			req:      &Request[any]{ID: ID{value: nil}, Method: "testMethod", Params: map[string]any{"param1": "value1"}},
-			expected: `{"jsonrpc":"2.0","method":"testMethod","params":{"param1":"value1"}}`,
-		},
-	}
-
-	for _, test := range tests {
-		result, err := json.Marshal(test.req)
-		if err != nil {
-			t.Errorf("MarshalJSON(%v) error: %v", test.req, err)
-		}
-		if string(result) != test.expected {
-			t.Errorf("MarshalJSON(%v) = %v; want %v", test.req, string(result), test.expected)
-		}
+func TestServeConn_BatchDispatchAndResponse(t *testing.T) {
+	serverIn, clientToServer := io.Pipe()
+	clientFromServer, serverOut := io.Pipe()
+
+	conn := NewConn(serverIn, serverOut, HeaderFraming)
+
+	handler := func(_ context.Context, req Request[json.RawMessage]) (any, error) {
+		return "echo:" + req.Method, nil
+	}
+
+	serveDone := make(chan error, 1)
+	go func() { serveDone <- conn.ServeConn(context.Background(), handler) }()
+
+	batchBody := `[{"jsonrpc":"2.0","id":1,"method":"one"},{"jsonrpc":"2.0","method":"notify"},{"jsonrpc":"2.0","id":2,"method":"two"}]`
+	go func() {
+		_, _ = io.WriteString(clientToServer, fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(batchBody), batchBody))
+		clientToServer.Close()
+	}()
+
+	respData, err := readHeaderFrame(bufio.NewReader(clientFromServer))
+	if err != nil {
+		t.Fatalf("readHeaderFrame() error: %v", err)
+	}
+
+	var resp BatchResponse
+	if err := json.Unmarshal(respData, &resp); err != nil {
+		t.Fatalf("json.Unmarshal() error: %v", err)
+	}
+	if len(resp) != 2 {
+		t.Fatalf("len(resp) = %d; want 2 (notification entry omitted)", len(resp))
+	}
+
+	byID := map[string]any{}
+	for _, r := range resp {
+		byID[r.ID.String()] = r.Result
+	}
+	if byID["1"] != "echo:one" {
+		t.Errorf("resp[id=1] = %v; want echo:one", byID["1"])
+	}
+	if byID["2"] != "echo:two" {
+		t.Errorf("resp[id=2] = %v; want echo:two", byID["2"])
+	}
+
+	if err := <-serveDone; err != io.EOF {
+		t.Errorf("ServeConn() error = %v; want io.EOF", err)
 	}
 }
 
-func TestRequest_UnmarshalJSON(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected Request[map[string]any]
-	}{
-		{
-			input:    `{"jsonrpc":"2.0","id":"1","method":"testMethod","params":{"param1":"value1"}}`,
-			expected: Request[map[string]any]{ID: ID{value: "1"}, Method: "testMethod", Params: map[string]any{"param1": "value1"}},
-		},
-		{
-			input:    `{"jsonrpc":"2.0","id":1,"method":"testMethod","params":{"param1":"value1"}}`,
-			expected: Request[map[string]any]{ID: ID{value: 1}, Method: "testMethod", Params: map[string]any{"param1": "value1"}},
-		},
-		{
-			input:    `{"jsonrpc":"2.0","method":"testMethod","params":{"param1":"value1"}}`,
-			expected: Request[map[string]any]{ID: ID{value: nil}, Method: "testMethod", Params: map[string]any{"param1": "value1"}},
-		},
-	}
-
-	for _, test := range tests {
-		var req Request[map[string]any]
-		if err := json.Unmarshal([]byte(test.input), &req); err != nil {
-			t.Errorf("UnmarshalJSON(%v) error: %v", test.input, err)
+func TestConn_Call_DistinguishesNumericAndStringID(t *testing.T) {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	client := NewConn(clientIn, clientOut, HeaderFraming)
+	server := NewConn(serverIn, serverOut, HeaderFraming)
+
+	noop := func(_ context.Context, _ Request[json.RawMessage]) (any, error) { return nil, nil }
+	go server.ServeConn(context.Background(), func(_ context.Context, req Request[json.RawMessage]) (any, error) {
+		return "reply:" + req.Method, nil
+	})
+	go client.ServeConn(context.Background(), noop)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	numDone := make(chan Response[json.RawMessage, json.RawMessage], 1)
+	strDone := make(chan Response[json.RawMessage, json.RawMessage], 1)
+	go func() {
+		resp, err := client.Call(ctx, Request[any]{ID: ID{value: int64(5)}, Method: "numeric"})
+		if err != nil {
+			t.Errorf("Call(numeric) error: %v", err)
 		}
-		if req.ID != test.expected.ID || req.Method != test.expected.Method || fmt.Sprintf("%v", req.Params) != fmt.Sprintf("%v", test.expected.Params) {
-			t.Errorf("UnmarshalJSON(%v) = %v; want %v", test.input, req, test.expected)
+		numDone <- resp
+	}()
+	go func() {
+		resp, err := client.Call(ctx, Request[any]{ID: ID{value: "5"}, Method: "string"})
+		if err != nil {
+			t.Errorf("Call(string) error: %v", err)
 		}
+		strDone <- resp
+	}()
+
+	numResp := <-numDone
+	strResp := <-strDone
+
+	var numResult, strResult string
+	if err := json.Unmarshal(numResp.Result, &numResult); err != nil {
+		t.Fatalf("json.Unmarshal(numResp.Result) error: %v", err)
+	}
+	if err := json.Unmarshal(strResp.Result, &strResult); err != nil {
+		t.Fatalf("json.Unmarshal(strResp.Result) error: %v", err)
+	}
+	if numResult != "reply:numeric" {
+		t.Errorf("numeric-ID call result = %v; want reply:numeric", numResult)
+	}
+	if strResult != "reply:string" {
+		t.Errorf("string-ID call result = %v; want reply:string", strResult)
 	}
 }
 
-func TestResponse_MarshalJSON(t *testing.T) {
-  tests := []struct {
-    resp     *Response[any, any]
-    expected string
-  }{
-    {
-      resp:     &Response[any, any]{ID: ID{value: "1"}, Result: "result", Error: Error[any]{}},
-      expected: `{"jsonrpc":"2.0","id":"1","result":"result"}`,
-    },
-    {
-      resp:     &Response[any, any]{ID: ID{value: 1}, Result: 123, Error: Error[any]{}},
-      expected: `{"jsonrpc":"2.0","id":1,"result":123}`,
-    },
-    {
-      resp:     &Response[any, any]{ID: ID{value: nil}, Result: nil, Error: Error[any]{Code: -32000, Message: "error"}},
-      expected: `{"jsonrpc":"2.0","error":{"code":-32000,"message":"error"}}`,
-    },
-  }
-
-  for _, test := range tests {
-    result, err := json.Marshal(test.resp)
-    if err != nil {
-      t.Errorf("MarshalJSON(%v) error: %v", test.resp, err)
-    }
-    if string(result) != test.expected {
-      t.Errorf("MarshalJSON(%v) = %v; want %v", test.resp, string(result), test.expected)
-    }
-  }
-}
+func TestConn_Call_MatchesResponseByID(t *testing.T) {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
 
-func TestResponse_UnmarshalJSON(t *testing.T) {
-  tests := []struct {
-    input    string
-    expected Response[any, any]
-  }{
-    {
-      input:    `{"jsonrpc":"2.0","id":"1","result":"result"}`,
-      expected: Response[any, any]{ID: ID{value: "1"}, Result: "result", Error: Error[any]{}},
-    },
-    {
-      input:    `{"jsonrpc":"2.0","id":1,"result":123}`,
-      expected: Response[any, any]{ID: ID{value: 1}, Result: 123, Error: Error[any]{}},
-    },
-    {
-      input:    `{"jsonrpc":"2.0","error":{"code":-32000,"message":"error"}}`,
-      expected: Response[any, any]{ID: ID{value: nil}, Result: nil, Error: Error[any]{Code: -32000, Message: "error"}},
-    },
-  }
-
-  for _, test := range tests {
-    var resp Response[any, any]
-    if err := json.Unmarshal([]byte(test.input), &resp); err != nil {
-      t.Errorf("UnmarshalJSON(%v) error: %v", test.input, err)
-    }
-    if resp.ID != test.expected.ID || fmt.Sprintf("%v", resp.Result) != fmt.Sprintf("%v", test.expected.Result) || resp.Error.Code != test.expected.Error.Code || resp.Error.Message != test.expected.Error.Message {
-      t.Errorf("UnmarshalJSON(%v) = %v; want %v", test.input, resp, test.expected)
-    }
-  }
+	client := NewConn(clientIn, clientOut, HeaderFraming)
+	server := NewConn(serverIn, serverOut, HeaderFraming)
+
+	noop := func(_ context.Context, _ Request[json.RawMessage]) (any, error) { return nil, nil }
+	go server.ServeConn(context.Background(), func(_ context.Context, req Request[json.RawMessage]) (any, error) {
+		return "pong", nil
+	})
+	go client.ServeConn(context.Background(), noop)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	resp, err := client.Call(ctx, Request[any]{ID: ID{value: int64(1)}, Method: "ping"})
+	if err != nil {
+		t.Fatalf("Call() error: %v", err)
+	}
+
+	var result string
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatalf("json.Unmarshal(Result) error: %v", err)
+	}
+	if result != "pong" {
+		t.Errorf("Result = %v; want pong", result)
+	}
 }
@@ -0,0 +1,89 @@
+package jsonrpc2
+
+import "fmt"
+
+// ErrorCode is a JSON-RPC/MCP error code, as carried in Error.Code.
+type ErrorCode int
+
+// Spec-reserved JSON-RPC 2.0 error codes. See
+// https://www.jsonrpc.org/specification#error_object.
+const (
+	ParseError     ErrorCode = -32700
+	InvalidRequest ErrorCode = -32600
+	MethodNotFound ErrorCode = -32601
+	InvalidParams  ErrorCode = -32602
+	InternalError  ErrorCode = -32603
+)
+
+// ResourceNotFound is the MCP-reserved code, within the spec's -32000 to
+// -32099 "server error" range, for a request that names a resource the
+// server doesn't have.
+const ResourceNotFound ErrorCode = -32002
+
+// Sentinel errors for the reserved codes above, usable with errors.Is:
+//
+//	if errors.Is(err, jsonrpc2.ErrMethodNotFound) { ... }
+//
+// Is compares by Code alone, so these match any Error carrying the same
+// code regardless of Message or Data.
+var (
+	ErrParseError       = Error[any]{Code: int(ParseError), Message: "Parse error"}
+	ErrInvalidRequest   = Error[any]{Code: int(InvalidRequest), Message: "Invalid Request"}
+	ErrMethodNotFound   = Error[any]{Code: int(MethodNotFound), Message: "Method not found"}
+	ErrInvalidParams    = Error[any]{Code: int(InvalidParams), Message: "Invalid params"}
+	ErrInternalError    = Error[any]{Code: int(InternalError), Message: "Internal error"}
+	ErrResourceNotFound = Error[any]{Code: int(ResourceNotFound), Message: "Resource not found"}
+)
+
+// NewParseError constructs the standard "Parse error" response, optionally
+// carrying caller-defined detail.
+func NewParseError(data any) Error[any] {
+	return Error[any]{Code: int(ParseError), Message: "Parse error", Data: data}
+}
+
+// NewInvalidRequest constructs the standard "Invalid Request" response.
+func NewInvalidRequest(data any) Error[any] {
+	return Error[any]{Code: int(InvalidRequest), Message: "Invalid Request", Data: data}
+}
+
+// NewMethodNotFound constructs a "Method not found" response naming the
+// offending method.
+func NewMethodNotFound(method string) Error[any] {
+	return Error[any]{Code: int(MethodNotFound), Message: fmt.Sprintf("Method not found: %s", method)}
+}
+
+// NewInvalidParams constructs the standard "Invalid params" response.
+func NewInvalidParams(data any) Error[any] {
+	return Error[any]{Code: int(InvalidParams), Message: "Invalid params", Data: data}
+}
+
+// NewInternalError constructs the standard "Internal error" response.
+func NewInternalError(data any) Error[any] {
+	return Error[any]{Code: int(InternalError), Message: "Internal error", Data: data}
+}
+
+// NewResourceNotFound constructs a "Resource not found" response naming
+// the offending resource.
+func NewResourceNotFound(uri string) Error[any] {
+	return Error[any]{Code: int(ResourceNotFound), Message: fmt.Sprintf("Resource not found: %s", uri)}
+}
+
+// Is implements the errors.Is comparison contract: two Errors match if
+// they carry the same Code, regardless of Message or Data. This lets
+// callers write errors.Is(err, jsonrpc2.ErrMethodNotFound).
+func (e Error[D]) Is(target error) bool {
+	te, ok := target.(interface{ code() int })
+	if !ok {
+		return false
+	}
+	return e.Code == te.code()
+}
+
+// Unwrap surfaces Data as the wrapped error when it implements error,
+// allowing errors.As to reach whatever caused this Error.
+func (e Error[D]) Unwrap() error {
+	if inner, ok := any(e.Data).(error); ok {
+		return inner
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package jsonrpc2
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewMethodNotFound(t *testing.T) {
+	err := NewMethodNotFound("sum")
+	if err.Code != int(MethodNotFound) {
+		t.Errorf("Code = %d; want %d", err.Code, MethodNotFound)
+	}
+	if err.Message != "Method not found: sum" {
+		t.Errorf("Message = %q; want %q", err.Message, "Method not found: sum")
+	}
+}
+
+func TestError_Is(t *testing.T) {
+	err := NewMethodNotFound("sum")
+
+	if !errors.Is(err, ErrMethodNotFound) {
+		t.Error("errors.Is(err, ErrMethodNotFound) = false; want true")
+	}
+	if errors.Is(err, ErrInvalidParams) {
+		t.Error("errors.Is(err, ErrInvalidParams) = true; want false")
+	}
+}
+
+func TestError_Unwrap(t *testing.T) {
+	inner := errors.New("boom")
+	err := convertError(inner)
+
+	if !errors.Is(err, inner) {
+		t.Error("errors.Is(err, inner) = false; want true")
+	}
+
+	var target *myError
+	wrapped := Error[any]{Code: -32000, Message: "wrapped", Data: &myError{}}
+	if !errors.As(wrapped, &target) {
+		t.Error("errors.As(wrapped, &target) = false; want true")
+	}
+}
+
+func TestError_Is_UncomparableData(t *testing.T) {
+	err := Error[any]{Code: int(MethodNotFound), Message: "Method not found: sum", Data: []string{"a"}}
+	target := Error[any]{Code: int(MethodNotFound), Message: "Method not found: sum", Data: []string{"a"}}
+
+	if !errors.Is(err, target) {
+		t.Error("errors.Is(err, target) = false; want true")
+	}
+	if errors.Is(err, ErrInvalidParams) {
+		t.Error("errors.Is(err, ErrInvalidParams) = true; want false")
+	}
+}
+
+type myError struct{}
+
+func (e *myError) Error() string { return "my error" }
@@ -0,0 +1,351 @@
+// Package jsonrpc2 implements the JSON-RPC 2.0 message types shared by MCP
+// clients and servers: request/response envelopes, identifiers, and errors.
+package jsonrpc2
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is the JSON-RPC protocol version emitted on every message.
+const Version = "2.0"
+
+// ErrFractionalID is returned by ID.UnmarshalJSON when a numeric ID
+// contains a fractional or exponent component. The spec says numeric IDs
+// SHOULD NOT contain fractional parts; we reject them outright to avoid
+// silently losing precision to float64.
+var ErrFractionalID = errors.New("jsonrpc2: numeric ID must not contain a fractional part")
+
+// ID is a JSON-RPC request identifier. Per the spec it is a string, a
+// number, or null; the zero value is the null ID.
+type ID struct {
+	value any
+}
+
+// IsNull reports whether the ID is the JSON null identifier.
+func (id ID) IsNull() bool {
+	return id.value == nil
+}
+
+// String returns the identifier rendered as a string. It panics if the ID
+// holds a value other than those produced by UnmarshalJSON.
+func (id ID) String() string {
+	switch v := id.value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	default:
+		panic(fmt.Sprintf("jsonrpc2: invalid ID type %T", id.value))
+	}
+}
+
+// AsInt64 returns the ID's value as an int64, and true if the ID holds a
+// numeric value.
+func (id ID) AsInt64() (int64, bool) {
+	switch v := id.value.(type) {
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// AsString returns the ID's value as a string, and true if the ID holds a
+// string value.
+func (id ID) AsString() (string, bool) {
+	s, ok := id.value.(string)
+	return s, ok
+}
+
+// MarshalJSON implements json.Marshaler.
+func (id ID) MarshalJSON() ([]byte, error) {
+	switch v := id.value.(type) {
+	case nil:
+		return []byte("null"), nil
+	case string:
+		return json.Marshal(v)
+	case int:
+		return json.Marshal(v)
+	case int64:
+		return json.Marshal(v)
+	default:
+		return nil, fmt.Errorf("jsonrpc2: invalid ID type %T", id.value)
+	}
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Numeric IDs are decoded via
+// json.Number and kept as int64 so that the full int64 range (including
+// values above math.MaxInt32) round-trips exactly; fractional or
+// exponent-bearing numbers are rejected with ErrFractionalID.
+func (id *ID) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	switch t := v.(type) {
+	case nil:
+		id.value = nil
+	case string:
+		id.value = t
+	case json.Number:
+		if strings.ContainsAny(string(t), ".eE") {
+			return ErrFractionalID
+		}
+		n, err := strconv.ParseInt(string(t), 10, 64)
+		if err != nil {
+			return fmt.Errorf("jsonrpc2: invalid numeric ID %q: %w", t, err)
+		}
+		id.value = n
+	default:
+		return fmt.Errorf("jsonrpc2: invalid ID type %T", v)
+	}
+	return nil
+}
+
+// Request is a JSON-RPC request or notification. A Request whose ID
+// IsNull is a notification and MUST NOT receive a Response.
+type Request[P any] struct {
+	ID     ID
+	Method string
+	Params P
+}
+
+// MarshalJSON implements json.Marshaler, omitting the id field for
+// notifications.
+func (r Request[P]) MarshalJSON() ([]byte, error) {
+	raw := struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id,omitempty"`
+		Method  string          `json:"method"`
+		Params  P               `json:"params,omitempty"`
+	}{
+		JSONRPC: Version,
+		Method:  r.Method,
+		Params:  r.Params,
+	}
+	if !r.ID.IsNull() {
+		idBytes, err := json.Marshal(r.ID)
+		if err != nil {
+			return nil, err
+		}
+		raw.ID = idBytes
+	}
+	return json.Marshal(raw)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *Request[P]) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Method  string          `json:"method"`
+		Params  P               `json:"params"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	r.Method = raw.Method
+	r.Params = raw.Params
+	if len(raw.ID) == 0 {
+		r.ID = ID{}
+		return nil
+	}
+	return json.Unmarshal(raw.ID, &r.ID)
+}
+
+// Response is a JSON-RPC response. Exactly one of Result or Error is
+// populated; a zero-valued Error indicates success.
+type Response[R, E any] struct {
+	ID     ID
+	Result R
+	Error  Error[E]
+}
+
+// MarshalJSON implements json.Marshaler, omitting result when Error is set
+// and omitting error when it is not.
+func (r Response[R, E]) MarshalJSON() ([]byte, error) {
+	raw := struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id,omitempty"`
+		Result  *R              `json:"result,omitempty"`
+		Error   *Error[E]       `json:"error,omitempty"`
+	}{
+		JSONRPC: Version,
+	}
+	if !r.ID.IsNull() {
+		idBytes, err := json.Marshal(r.ID)
+		if err != nil {
+			return nil, err
+		}
+		raw.ID = idBytes
+	}
+	if r.Error.isZero() {
+		raw.Result = &r.Result
+	} else {
+		raw.Error = &r.Error
+	}
+	return json.Marshal(raw)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *Response[R, E]) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Result  R               `json:"result"`
+		Error   Error[E]        `json:"error"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	r.Result = raw.Result
+	r.Error = raw.Error
+	if len(raw.ID) == 0 {
+		r.ID = ID{}
+		return nil
+	}
+	return json.Unmarshal(raw.ID, &r.ID)
+}
+
+// Error is a JSON-RPC error object. Data carries caller-defined detail and
+// is omitted from the wire form when not set.
+//
+// The blank [0]func() field makes Error incomparable with ==. Without it,
+// errors.Is's pre-check (err == target, attempted whenever reflection says
+// the type "supports" == at all) would reach into Data and panic if two
+// Errors of the same instantiation held an uncomparable dynamic value, such
+// as a slice or map. Incomparable forces errors.Is straight to the Is
+// method in errors.go, which only ever looks at Code.
+type Error[D any] struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    D      `json:"data,omitempty"`
+
+	_ [0]func()
+}
+
+// Error implements the error interface.
+func (e Error[D]) Error() string {
+	return e.Message
+}
+
+func (e Error[D]) code() int {
+	return e.Code
+}
+
+func (e Error[D]) message() string {
+	return e.Message
+}
+
+// data returns Data boxed as any regardless of D, so that every
+// instantiation of Error[D] — not just Error[any] — satisfies richError.
+// A signature of "data() D" would only match richError's "data() any" when
+// D happened to be any; Go requires an exact method signature match, so
+// Error[string], Error[json.RawMessage], and so on would silently fail the
+// richError assertion in convertError and lose their real Code/Data.
+func (e Error[D]) data() any {
+	return e.Data
+}
+
+func (e Error[D]) isZero() bool {
+	return e.Code == 0 && e.Message == ""
+}
+
+// richError is implemented by errors that already carry a JSON-RPC code,
+// message and data, such as Error[D] itself (any instantiation).
+type richError interface {
+	error
+	code() int
+	message() string
+	data() any
+}
+
+// NewError constructs an Error[any] carrying the given code, message and
+// caller-defined data.
+func NewError(code int, message string, data any) Error[any] {
+	return Error[any]{Code: code, Message: message, Data: data}
+}
+
+// convertError adapts an arbitrary error into a JSON-RPC Error[any],
+// preserving code/message/data for errors that already provide them and
+// otherwise falling back to the generic server error code.
+func convertError(err error) Error[any] {
+	if e, ok := err.(richError); ok {
+		return Error[any]{Code: e.code(), Message: e.message(), Data: e.data()}
+	}
+	return Error[any]{Code: -32000, Message: err.Error(), Data: err}
+}
+
+// messageType classifies a decoded JSON-RPC message.
+type messageType int
+
+const (
+	messageRequest messageType = iota + 1
+	messageNotification
+	messageResponse
+	messageBatch
+)
+
+// getMessageType inspects a raw JSON-RPC message and classifies it as a
+// request, a notification, a response, or a batch.
+func getMessageType(data json.RawMessage) (messageType, error) {
+	if isBatch(data) {
+		return messageBatch, nil
+	}
+
+	var probe struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Method  string          `json:"method"`
+		Result  json.RawMessage `json:"result"`
+		Error   json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return 0, err
+	}
+	if probe.JSONRPC != Version {
+		return 0, errors.New("invalid JSON-RPC version")
+	}
+	switch {
+	case probe.Method != "":
+		if len(probe.ID) > 0 {
+			return messageRequest, nil
+		}
+		return messageNotification, nil
+	case len(probe.Error) > 0:
+		return messageResponse, nil
+	case len(probe.Result) > 0 && len(probe.ID) > 0:
+		return messageResponse, nil
+	default:
+		return 0, errors.New("invalid message type")
+	}
+}
+
+// isBatch peeks at the first non-whitespace byte of data to determine
+// whether it is a JSON array rather than a single object.
+func isBatch(data json.RawMessage) bool {
+	for _, b := range data {
+		switch b {
+		case ' ', '\t', '\n', '\r':
+			continue
+		case '[':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
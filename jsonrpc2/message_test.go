@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"strconv"
 	"testing"
 )
 
@@ -58,7 +60,7 @@ func TestID_UnmarshalJSON(t *testing.T) {
 		expected ID
 	}{
 		{input: `"test"`, expected: ID{value: "test"}},
-		{input: `123`, expected: ID{value: 123}},
+		{input: `123`, expected: ID{value: int64(123)}},
 		{input: `null`, expected: ID{value: nil}},
 	}
 
@@ -73,6 +75,63 @@ func TestID_UnmarshalJSON(t *testing.T) {
 	}
 }
 
+func TestID_UnmarshalJSON_LargeInt64(t *testing.T) {
+	var id ID
+	input := strconv.FormatInt(math.MaxInt64, 10)
+	if err := json.Unmarshal([]byte(input), &id); err != nil {
+		t.Fatalf("UnmarshalJSON(%v) error: %v", input, err)
+	}
+	got, ok := id.AsInt64()
+	if !ok || got != math.MaxInt64 {
+		t.Errorf("AsInt64() = %v, %v; want %v, true", got, ok, int64(math.MaxInt64))
+	}
+	result, err := json.Marshal(id)
+	if err != nil {
+		t.Fatalf("MarshalJSON() error: %v", err)
+	}
+	if string(result) != input {
+		t.Errorf("MarshalJSON() = %v; want %v", string(result), input)
+	}
+}
+
+func TestID_AsInt64(t *testing.T) {
+	tests := []struct {
+		id     ID
+		want   int64
+		wantOk bool
+	}{
+		{id: ID{value: int64(123)}, want: 123, wantOk: true},
+		{id: ID{value: "test"}, want: 0, wantOk: false},
+		{id: ID{value: nil}, want: 0, wantOk: false},
+	}
+
+	for _, test := range tests {
+		got, ok := test.id.AsInt64()
+		if got != test.want || ok != test.wantOk {
+			t.Errorf("ID(%v).AsInt64() = %v, %v; want %v, %v", test.id, got, ok, test.want, test.wantOk)
+		}
+	}
+}
+
+func TestID_AsString(t *testing.T) {
+	tests := []struct {
+		id     ID
+		want   string
+		wantOk bool
+	}{
+		{id: ID{value: "test"}, want: "test", wantOk: true},
+		{id: ID{value: int64(123)}, want: "", wantOk: false},
+		{id: ID{value: nil}, want: "", wantOk: false},
+	}
+
+	for _, test := range tests {
+		got, ok := test.id.AsString()
+		if got != test.want || ok != test.wantOk {
+			t.Errorf("ID(%v).AsString() = %v, %v; want %v, %v", test.id, got, ok, test.want, test.wantOk)
+		}
+	}
+}
+
 func TestID_UnmarshalJSON_InvalidType(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -94,6 +153,21 @@ func TestID_UnmarshalJSON_InvalidType(t *testing.T) {
 			json:    `{"key":"value"}`,
 			wantErr: true,
 		},
+		{
+			name:    "fractional",
+			json:    `1.5`,
+			wantErr: true,
+		},
+		{
+			name:    "exponent",
+			json:    `1e2`,
+			wantErr: true,
+		},
+		{
+			name:    "overflows int64",
+			json:    `9999999999999999999`,
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -177,7 +251,7 @@ func TestRequest_UnmarshalJSON(t *testing.T) {
 		},
 		{
 			input:    `{"jsonrpc":"2.0","id":1,"method":"testMethod","params":{"param1":"value1"}}`,
-			expected: Request[map[string]any]{ID: ID{value: 1}, Method: "testMethod", Params: map[string]any{"param1": "value1"}},
+			expected: Request[map[string]any]{ID: ID{value: int64(1)}, Method: "testMethod", Params: map[string]any{"param1": "value1"}},
 		},
 		{
 			input:    `{"jsonrpc":"2.0","method":"testMethod","params":{"param1":"value1"}}`,
@@ -237,7 +311,7 @@ func TestResponse_UnmarshalJSON(t *testing.T) {
 		},
 		{
 			input:    `{"jsonrpc":"2.0","id":1,"result":123}`,
-			expected: Response[any, any]{ID: ID{value: 1}, Result: 123, Error: Error[any]{}},
+			expected: Response[any, any]{ID: ID{value: int64(1)}, Result: 123, Error: Error[any]{}},
 		},
 		{
 			input:    `{"jsonrpc":"2.0","error":{"code":-32000,"message":"error"}}`,
@@ -343,6 +417,31 @@ func TestConvertError(t *testing.T) {
 	}
 }
 
+func TestConvertError_PreservesConcreteInstantiation(t *testing.T) {
+	err := Error[string]{Code: 42, Message: "custom", Data: "detail"}
+
+	converted := convertError(err)
+	if converted.Code != 42 {
+		t.Errorf("Code = %d; want 42", converted.Code)
+	}
+	if converted.Message != "custom" {
+		t.Errorf("Message = %q; want %q", converted.Message, "custom")
+	}
+	if converted.Data != "detail" {
+		t.Errorf("Data = %v; want %v", converted.Data, "detail")
+	}
+}
+
+func TestConvertError_Registry(t *testing.T) {
+	err := convertError(NewMethodNotFound("sum"))
+	if !errors.Is(err, ErrMethodNotFound) {
+		t.Error("errors.Is(err, ErrMethodNotFound) = false; want true")
+	}
+	if errors.Is(err, ErrInternalError) {
+		t.Error("errors.Is(err, ErrInternalError) = true; want false")
+	}
+}
+
 type customError struct {
 	errCode    int
 	errMessage string
@@ -420,6 +519,16 @@ func TestGetMessageType(t *testing.T) {
 			expected: 0,
 			err:      errors.New("invalid JSON-RPC version"),
 		},
+		{
+			input:    `[{"jsonrpc":"2.0","method":"testMethod","id":1},{"jsonrpc":"2.0","method":"notify"}]`,
+			expected: messageBatch,
+			err:      nil,
+		},
+		{
+			input:    "  \n\t[{\"jsonrpc\":\"2.0\",\"method\":\"testMethod\"}]",
+			expected: messageBatch,
+			err:      nil,
+		},
 	}
 
 	for _, test := range tests {
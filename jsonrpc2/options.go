@@ -0,0 +1,134 @@
+package jsonrpc2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// UnmarshalOption configures the strictness of DecodeRequest and
+// DecodeResponse, following the functional-option pattern used by
+// sigs.k8s.io/json's UnmarshalStrict.
+type UnmarshalOption func(*unmarshalOptions)
+
+type unmarshalOptions struct {
+	disallowUnknownFields bool
+	useNumber             bool
+	strictVersion         bool
+}
+
+// DisallowUnknownFields causes decoding to fail if the message contains
+// any top-level field beyond jsonrpc/id/method/params (requests) or
+// jsonrpc/id/result/error (responses). Extra top-level fields are a
+// common symptom of a peer that has drifted from the protocol.
+func DisallowUnknownFields() UnmarshalOption {
+	return func(o *unmarshalOptions) { o.disallowUnknownFields = true }
+}
+
+// UseNumber decodes with json.Decoder.UseNumber enabled. DecodeRequest
+// and DecodeResponse return Params, Result, and Error.Data undecoded as
+// json.RawMessage, so UseNumber has no effect on them by itself; pass it
+// again to DecodeValue when unmarshaling those fields to preserve their
+// numeric literals as json.Number instead of collapsing them to float64.
+func UseNumber() UnmarshalOption {
+	return func(o *unmarshalOptions) { o.useNumber = true }
+}
+
+// StrictVersion rejects any message whose "jsonrpc" field is not exactly
+// Version. A plain json.Unmarshal into Request or Response never checks
+// this field at all; only getMessageType does, and only for one of its
+// code paths.
+func StrictVersion() UnmarshalOption {
+	return func(o *unmarshalOptions) { o.strictVersion = true }
+}
+
+func newUnmarshalOptions(opts []UnmarshalOption) unmarshalOptions {
+	var o unmarshalOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func (o unmarshalOptions) decode(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if o.disallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if o.useNumber {
+		dec.UseNumber()
+	}
+	return dec.Decode(v)
+}
+
+// DecodeValue unmarshals data into v with the given UnmarshalOptions
+// applied, the same way DecodeRequest and DecodeResponse decode their
+// envelope. Pass the UseNumber option here, matching whatever was passed
+// to DecodeRequest/DecodeResponse, to decode their returned Params,
+// Result, or Error.Data with json.Number preserved end to end.
+func DecodeValue(data []byte, v any, opts ...UnmarshalOption) error {
+	return newUnmarshalOptions(opts).decode(data, v)
+}
+
+// DecodeRequest decodes a single JSON-RPC request or notification,
+// applying the given UnmarshalOptions. Params is returned undecoded so
+// callers can unmarshal it into their own parameter type.
+func DecodeRequest(data []byte, opts ...UnmarshalOption) (Request[json.RawMessage], error) {
+	o := newUnmarshalOptions(opts)
+
+	var raw struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Method  string          `json:"method"`
+		Params  json.RawMessage `json:"params"`
+	}
+	if err := o.decode(data, &raw); err != nil {
+		return Request[json.RawMessage]{}, err
+	}
+	if o.strictVersion && raw.JSONRPC != Version {
+		return Request[json.RawMessage]{}, fmt.Errorf("jsonrpc2: invalid JSON-RPC version %q", raw.JSONRPC)
+	}
+
+	req := Request[json.RawMessage]{Method: raw.Method, Params: raw.Params}
+	if len(raw.ID) > 0 {
+		if err := json.Unmarshal(raw.ID, &req.ID); err != nil {
+			return Request[json.RawMessage]{}, err
+		}
+	}
+	return req, nil
+}
+
+// DecodeResponse decodes a single JSON-RPC response, applying the given
+// UnmarshalOptions. Result and Error.Data are returned undecoded so
+// callers can unmarshal them into their own result/error-data types.
+func DecodeResponse(data []byte, opts ...UnmarshalOption) (Response[json.RawMessage, json.RawMessage], error) {
+	o := newUnmarshalOptions(opts)
+
+	var raw struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Result  json.RawMessage `json:"result"`
+		Error   *struct {
+			Code    int             `json:"code"`
+			Message string          `json:"message"`
+			Data    json.RawMessage `json:"data"`
+		} `json:"error"`
+	}
+	if err := o.decode(data, &raw); err != nil {
+		return Response[json.RawMessage, json.RawMessage]{}, err
+	}
+	if o.strictVersion && raw.JSONRPC != Version {
+		return Response[json.RawMessage, json.RawMessage]{}, fmt.Errorf("jsonrpc2: invalid JSON-RPC version %q", raw.JSONRPC)
+	}
+
+	resp := Response[json.RawMessage, json.RawMessage]{Result: raw.Result}
+	if raw.Error != nil {
+		resp.Error = Error[json.RawMessage]{Code: raw.Error.Code, Message: raw.Error.Message, Data: raw.Error.Data}
+	}
+	if len(raw.ID) > 0 {
+		if err := json.Unmarshal(raw.ID, &resp.ID); err != nil {
+			return Response[json.RawMessage, json.RawMessage]{}, err
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,115 @@
+package jsonrpc2
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDecodeRequest_Plain(t *testing.T) {
+	input := `{"jsonrpc":"2.0","id":1,"method":"testMethod","params":{"param1":"value1"}}`
+
+	req, err := DecodeRequest([]byte(input))
+	if err != nil {
+		t.Fatalf("DecodeRequest() error: %v", err)
+	}
+	if req.Method != "testMethod" {
+		t.Errorf("Method = %v; want testMethod", req.Method)
+	}
+	if got, ok := req.ID.AsInt64(); !ok || got != 1 {
+		t.Errorf("ID.AsInt64() = %v, %v; want 1, true", got, ok)
+	}
+}
+
+func TestDecodeRequest_DisallowUnknownFields(t *testing.T) {
+	input := `{"jsonrpc":"2.0","id":1,"method":"testMethod","extra":true}`
+
+	if _, err := DecodeRequest([]byte(input), DisallowUnknownFields()); err == nil {
+		t.Error("DecodeRequest() error = nil; want error for unknown field")
+	}
+	if _, err := DecodeRequest([]byte(input)); err != nil {
+		t.Errorf("DecodeRequest() without option error = %v; want nil", err)
+	}
+}
+
+func TestDecodeRequest_StrictVersion(t *testing.T) {
+	input := `{"jsonrpc":"1.0","id":1,"method":"testMethod"}`
+
+	if _, err := DecodeRequest([]byte(input), StrictVersion()); err == nil {
+		t.Error("DecodeRequest() error = nil; want error for invalid version")
+	}
+	if _, err := DecodeRequest([]byte(input)); err != nil {
+		t.Errorf("DecodeRequest() without option error = %v; want nil", err)
+	}
+}
+
+func TestDecodeResponse_Plain(t *testing.T) {
+	input := `{"jsonrpc":"2.0","id":1,"result":{"count":123}}`
+
+	resp, err := DecodeResponse([]byte(input))
+	if err != nil {
+		t.Fatalf("DecodeResponse() error: %v", err)
+	}
+	if got, ok := resp.ID.AsInt64(); !ok || got != 1 {
+		t.Errorf("ID.AsInt64() = %v, %v; want 1, true", got, ok)
+	}
+
+	var result map[string]json.Number
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatalf("json.Unmarshal(Result) error: %v", err)
+	}
+	if result["count"] != "123" {
+		t.Errorf("result[count] = %v; want 123", result["count"])
+	}
+}
+
+func TestDecodeResponse_Error(t *testing.T) {
+	input := `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"boom","data":"detail"}}`
+
+	resp, err := DecodeResponse([]byte(input))
+	if err != nil {
+		t.Fatalf("DecodeResponse() error: %v", err)
+	}
+	if resp.Error.Code != -32000 || resp.Error.Message != "boom" {
+		t.Errorf("Error = %+v; want code -32000 message boom", resp.Error)
+	}
+	var data string
+	if err := json.Unmarshal(resp.Error.Data, &data); err != nil {
+		t.Fatalf("json.Unmarshal(Error.Data) error: %v", err)
+	}
+	if data != "detail" {
+		t.Errorf("Error.Data = %v; want detail", data)
+	}
+}
+
+func TestDecodeResponse_DisallowUnknownFields(t *testing.T) {
+	input := `{"jsonrpc":"2.0","id":1,"result":1,"extra":true}`
+
+	if _, err := DecodeResponse([]byte(input), DisallowUnknownFields()); err == nil {
+		t.Error("DecodeResponse() error = nil; want error for unknown field")
+	}
+}
+
+func TestDecodeValue_UseNumber(t *testing.T) {
+	input := `{"jsonrpc":"2.0","id":1,"result":{"count":123}}`
+
+	resp, err := DecodeResponse([]byte(input))
+	if err != nil {
+		t.Fatalf("DecodeResponse() error: %v", err)
+	}
+
+	var plain map[string]any
+	if err := DecodeValue(resp.Result, &plain); err != nil {
+		t.Fatalf("DecodeValue() error: %v", err)
+	}
+	if _, ok := plain["count"].(float64); !ok {
+		t.Errorf("plain[count] = %T; want float64 without UseNumber", plain["count"])
+	}
+
+	var numeric map[string]any
+	if err := DecodeValue(resp.Result, &numeric, UseNumber()); err != nil {
+		t.Fatalf("DecodeValue() with UseNumber error: %v", err)
+	}
+	if _, ok := numeric["count"].(json.Number); !ok {
+		t.Errorf("numeric[count] = %T; want json.Number with UseNumber", numeric["count"])
+	}
+}